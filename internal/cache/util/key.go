@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Key is a key for the cache. It can be used to retrieve a single object
+// or, when Name is empty, a list of objects.
+type Key struct {
+	Namespace  string
+	APIVersion string
+	Kind       string
+	Name       string
+
+	// FieldSelector filters list results by matching against fields that
+	// have been indexed on the cache's informer, e.g. "spec.nodeName=foo".
+	// Get ignores it. List applies it whenever it is set, and then does
+	// not also filter by Name.
+	FieldSelector string
+}
+
+// GroupVersionKind returns the key's group, version, kind.
+func (k Key) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(k.APIVersion, k.Kind)
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s %s: namespace=%s name=%s fieldSelector=%q",
+		k.APIVersion, k.Kind, k.Namespace, k.Name, k.FieldSelector)
+}
@@ -0,0 +1,599 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	clientgocache "k8s.io/client-go/tools/cache"
+
+	cacheutil "github.com/heptio/developer-dash/internal/cache/util"
+	"github.com/heptio/developer-dash/internal/cluster"
+)
+
+// initFactoryFunc creates a cluster-scoped dynamic shared informer factory
+// for a cluster client.
+type initFactoryFunc func(client cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error)
+
+func defaultInitFactoryFunc(client cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	dynamicClient, err := client.DynamicClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create dynamic client")
+	}
+
+	return dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, 0), nil
+}
+
+// namespacedInitFactoryFunc creates a dynamic shared informer factory scoped
+// to a single namespace.
+type namespacedInitFactoryFunc func(client cluster.ClientInterface, namespace string) (dynamicinformer.DynamicSharedInformerFactory, error)
+
+func defaultNamespacedInitFactoryFunc(client cluster.ClientInterface, namespace string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+	dynamicClient, err := client.DynamicClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "create dynamic client")
+	}
+
+	return dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, 0, namespace, nil), nil
+}
+
+// WatchOption is a functional option for configuring Watch. It is primarily
+// used to inject test doubles.
+type WatchOption func(w *Watch)
+
+// WithNamespaces scopes Watch to a fixed set of namespaces. Instead of a
+// single cluster-scoped informer factory, Watch builds one factory per
+// namespace so memory use stays proportional to the namespaces Octant is
+// configured to show. Cluster-scoped resources are unaffected and continue
+// to use the cluster-wide factory.
+func WithNamespaces(namespaces ...string) WatchOption {
+	return func(w *Watch) {
+		w.namespaces = namespaces
+	}
+}
+
+// gvkKey identifies an informer by GVK and, for namespace-scoped Watch
+// instances, the namespace it was started for. Namespace is always empty
+// when Watch is not namespace-scoped.
+type gvkKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+}
+
+// Watch is a cache that is backed by informers watching the cluster. Objects
+// are served from backendCache until the relevant informer's factory has
+// been started, after which they are served from cachedObjects in memory.
+type Watch struct {
+	client                    cluster.ClientInterface
+	stopCh                    <-chan struct{}
+	initFactoryFunc           initFactoryFunc
+	namespacedInitFactoryFunc namespacedInitFactoryFunc
+	backendCache              Cache
+
+	namespaces         []string
+	factory            dynamicinformer.DynamicSharedInformerFactory
+	namespaceFactories map[string]dynamicinformer.DynamicSharedInformerFactory
+
+	mu            sync.RWMutex
+	watchedGVKs   map[gvkKey]bool
+	cachedObjects map[gvkKey]map[types.UID]*unstructured.Unstructured
+	informers     map[gvkKey]clientgocache.SharedIndexInformer
+	started       map[gvkKey]bool
+	indexedFields map[schema.GroupVersionKind]map[string]bool
+
+	subscriptionsMu   sync.RWMutex
+	nextRegistationID uint64
+	subscribers       map[gvkKey]map[RegistrationID]ResourceEventHandler
+	registrations     map[RegistrationID]gvkKey
+}
+
+// RegistrationID identifies a subscription registered with AddEventHandler.
+type RegistrationID uint64
+
+// ResourceEventHandler is notified of changes to objects of a watched GVK.
+// Implementations must be safe to call from multiple goroutines.
+type ResourceEventHandler interface {
+	OnAdd(u *unstructured.Unstructured)
+	OnUpdate(u *unstructured.Unstructured)
+	OnDelete(u *unstructured.Unstructured)
+}
+
+var _ Cache = (*Watch)(nil)
+
+// NewWatch creates an instance of Watch.
+func NewWatch(client cluster.ClientInterface, stopCh <-chan struct{}, options ...WatchOption) (*Watch, error) {
+	w := &Watch{
+		client:                    client,
+		stopCh:                    stopCh,
+		initFactoryFunc:           defaultInitFactoryFunc,
+		namespacedInitFactoryFunc: defaultNamespacedInitFactoryFunc,
+		watchedGVKs:               make(map[gvkKey]bool),
+		cachedObjects:             make(map[gvkKey]map[types.UID]*unstructured.Unstructured),
+		informers:                 make(map[gvkKey]clientgocache.SharedIndexInformer),
+		started:                   make(map[gvkKey]bool),
+		indexedFields:             make(map[schema.GroupVersionKind]map[string]bool),
+		subscribers:               make(map[gvkKey]map[RegistrationID]ResourceEventHandler),
+		registrations:             make(map[RegistrationID]gvkKey),
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	factory, err := w.initFactoryFunc(client)
+	if err != nil {
+		return nil, errors.Wrap(err, "initialize informer factory")
+	}
+	w.factory = factory
+
+	if len(w.namespaces) > 0 {
+		w.namespaceFactories = make(map[string]dynamicinformer.DynamicSharedInformerFactory, len(w.namespaces))
+		for _, ns := range w.namespaces {
+			nsFactory, err := w.namespacedInitFactoryFunc(client, ns)
+			if err != nil {
+				return nil, errors.Wrapf(err, "initialize informer factory for namespace %q", ns)
+			}
+			w.namespaceFactories[ns] = nsFactory
+		}
+	}
+
+	return w, nil
+}
+
+// isNamespaceScoped returns true when Watch is configured to serve namespace
+// out of a dedicated per-namespace factory.
+func (w *Watch) isNamespaceScoped(namespace string) bool {
+	for _, ns := range w.namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheKeyFor returns the gvkKey objects for gvk/namespace are stored under.
+// Namespaces that Watch was not configured for (via WithNamespaces) fall
+// back to the shared, cluster-wide key.
+func (w *Watch) cacheKeyFor(gvk schema.GroupVersionKind, namespace string) gvkKey {
+	if namespace != "" && w.isNamespaceScoped(namespace) {
+		return gvkKey{gvk: gvk, namespace: namespace}
+	}
+	return gvkKey{gvk: gvk}
+}
+
+// factoryFor returns the informer factory that should be used to watch
+// namespace. Namespaces Watch was not configured for share the cluster-wide
+// factory, as do cluster-scoped resources (namespace == "").
+func (w *Watch) factoryFor(namespace string) dynamicinformer.DynamicSharedInformerFactory {
+	if namespace != "" && w.isNamespaceScoped(namespace) {
+		return w.namespaceFactories[namespace]
+	}
+	return w.factory
+}
+
+// IndexField registers a custom indexer for gvk's cluster-wide informer,
+// keyed by field. extractor should return the set of index values an object
+// should be looked up by. A real SharedIndexInformer refuses AddIndexers
+// once it has started, so IndexField only registers the indexer and never
+// starts the factory itself — that way multiple fields (e.g. both
+// spec.nodeName and status.phase on Pod) can each be indexed with their own
+// IndexField call before List/Get/WaitForCacheSync/AddEventHandler first
+// watches the GVK and starts it. IndexField returns an error if the
+// informer has already started. It is not supported for namespace-scoped
+// informers started via WithNamespaces.
+func (w *Watch) IndexField(gvk schema.GroupVersionKind, field string, extractor func(*unstructured.Unstructured) []string) error {
+	informer, _, started, err := w.getOrCreateInformer(gvk, "")
+	if err != nil {
+		return errors.Wrapf(err, "index field %q for %s", field, gvk)
+	}
+
+	if started {
+		return errors.Errorf("index field %q for %s: informer is already started; call IndexField before the GVK is first watched", field, gvk)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.indexedFields[gvk][field] {
+		return nil
+	}
+
+	indexers := clientgocache.Indexers{
+		field: func(obj interface{}) ([]string, error) {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return nil, errors.Errorf("expected *unstructured.Unstructured, got %T", obj)
+			}
+			return extractor(u), nil
+		},
+	}
+
+	if err := informer.AddIndexers(indexers); err != nil {
+		return errors.Wrapf(err, "add indexer %q for %s", field, gvk)
+	}
+
+	if w.indexedFields[gvk] == nil {
+		w.indexedFields[gvk] = make(map[string]bool)
+	}
+	w.indexedFields[gvk][field] = true
+
+	return nil
+}
+
+// ensureWatching registers an informer for gvk in namespace and starts its
+// factory, doing so the first time a GVK/namespace pair is requested.
+// namespace should be "" for cluster-scoped resources. It is safe to call
+// multiple times for the same gvk/namespace.
+func (w *Watch) ensureWatching(gvk schema.GroupVersionKind, namespace string) (clientgocache.SharedIndexInformer, error) {
+	informer, key, started, err := w.getOrCreateInformer(gvk, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !started {
+		w.startFactory(key, namespace)
+	}
+
+	return informer, nil
+}
+
+// getOrCreateInformer returns the informer for gvk/namespace, creating it
+// (and registering the fan-out event handler) if necessary. It never starts
+// the informer's factory — callers that are ready for events to start
+// flowing must call startFactory themselves, after e.g. registering any
+// indexers IndexField needs in place before the informer starts.
+func (w *Watch) getOrCreateInformer(gvk schema.GroupVersionKind, namespace string) (informer clientgocache.SharedIndexInformer, key gvkKey, started bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	key = w.cacheKeyFor(gvk, namespace)
+
+	if informer, ok := w.informers[key]; ok {
+		return informer, key, w.started[key], nil
+	}
+
+	gvr, err := w.client.Resource(gvk.GroupKind())
+	if err != nil {
+		return nil, key, false, errors.Wrapf(err, "resolve resource for %s", gvk)
+	}
+
+	factory := w.factoryFor(namespace)
+
+	genericInformer := factory.ForResource(gvr)
+	sharedIndexInformer := genericInformer.Informer()
+
+	sharedIndexInformer.AddEventHandler(w.eventHandlerFuncs(key))
+
+	w.informers[key] = sharedIndexInformer
+
+	return sharedIndexInformer, key, false, nil
+}
+
+// startFactory starts the informer factory backing key/namespace, unless it
+// has already been started. Once started, key's GVK is considered watched:
+// List/Get begin serving it from cachedObjects instead of backendCache.
+func (w *Watch) startFactory(key gvkKey, namespace string) {
+	w.mu.Lock()
+	if w.started[key] {
+		w.mu.Unlock()
+		return
+	}
+	w.started[key] = true
+	w.watchedGVKs[key] = true
+	w.mu.Unlock()
+
+	w.factoryFor(namespace).Start(w.stopCh)
+}
+
+func (w *Watch) eventHandlerFuncs(key gvkKey) clientgocache.ResourceEventHandlerFuncs {
+	return clientgocache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.storeObject(key, obj)
+			w.dispatch(key, obj, func(h ResourceEventHandler, u *unstructured.Unstructured) { h.OnAdd(u) })
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			w.storeObject(key, obj)
+			w.dispatch(key, obj, func(h ResourceEventHandler, u *unstructured.Unstructured) { h.OnUpdate(u) })
+		},
+		DeleteFunc: func(obj interface{}) {
+			w.deleteObject(key, obj)
+			w.dispatch(key, obj, func(h ResourceEventHandler, u *unstructured.Unstructured) { h.OnDelete(u) })
+		},
+	}
+}
+
+// AddEventHandler subscribes handler to add/update/delete events for gvk's
+// cluster-wide informer, starting it if necessary. It is not supported for
+// namespace-scoped informers started via WithNamespaces.
+func (w *Watch) AddEventHandler(gvk schema.GroupVersionKind, handler ResourceEventHandler) (RegistrationID, error) {
+	key := w.cacheKeyFor(gvk, "")
+
+	if _, err := w.ensureWatching(gvk, ""); err != nil {
+		return 0, errors.Wrapf(err, "add event handler for %s", gvk)
+	}
+
+	w.subscriptionsMu.Lock()
+	defer w.subscriptionsMu.Unlock()
+
+	w.nextRegistationID++
+	id := RegistrationID(w.nextRegistationID)
+
+	if w.subscribers[key] == nil {
+		w.subscribers[key] = make(map[RegistrationID]ResourceEventHandler)
+	}
+	w.subscribers[key][id] = handler
+	w.registrations[id] = key
+
+	return id, nil
+}
+
+// RemoveEventHandler unsubscribes a handler previously registered with
+// AddEventHandler. It is a no-op if id is not currently registered.
+func (w *Watch) RemoveEventHandler(id RegistrationID) {
+	w.subscriptionsMu.Lock()
+	defer w.subscriptionsMu.Unlock()
+
+	key, ok := w.registrations[id]
+	if !ok {
+		return
+	}
+
+	delete(w.subscribers[key], id)
+	delete(w.registrations, id)
+}
+
+func (w *Watch) dispatch(key gvkKey, obj interface{}, notify func(ResourceEventHandler, *unstructured.Unstructured)) {
+	u, ok := unstructuredFromEvent(obj)
+	if !ok {
+		return
+	}
+
+	w.subscriptionsMu.RLock()
+	handlers := make([]ResourceEventHandler, 0, len(w.subscribers[key]))
+	for _, handler := range w.subscribers[key] {
+		handlers = append(handlers, handler)
+	}
+	w.subscriptionsMu.RUnlock()
+
+	for _, handler := range handlers {
+		notify(handler, u)
+	}
+}
+
+func unstructuredFromEvent(obj interface{}) (*unstructured.Unstructured, bool) {
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		return u, true
+	}
+
+	if tombstone, ok := obj.(clientgocache.DeletedFinalStateUnknown); ok {
+		u, ok := tombstone.Obj.(*unstructured.Unstructured)
+		return u, ok
+	}
+
+	return nil, false
+}
+
+func (w *Watch) storeObject(key gvkKey, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cachedObjects[key] == nil {
+		w.cachedObjects[key] = make(map[types.UID]*unstructured.Unstructured)
+	}
+	w.cachedObjects[key][u.GetUID()] = u
+}
+
+func (w *Watch) deleteObject(key gvkKey, obj interface{}) {
+	u, ok := unstructuredFromEvent(obj)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.cachedObjects[key], u.GetUID())
+}
+
+// List lists objects matching key. A FieldSelector requires the field to
+// already be indexed via IndexField; List then starts (or reuses) that
+// GVK's informer and serves the selector from its indexer. Without a
+// FieldSelector, objects are served from the local cache once the
+// relevant GVK/namespace is being watched, and from the backend cache
+// otherwise.
+func (w *Watch) List(ctx context.Context, key cacheutil.Key) ([]*unstructured.Unstructured, error) {
+	gvk := key.GroupVersionKind()
+	cacheKey := w.cacheKeyFor(gvk, key.Namespace)
+
+	if key.FieldSelector != "" {
+		field, _, err := splitFieldSelector(key.FieldSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		w.mu.RLock()
+		indexed := w.indexedFields[gvk][field]
+		w.mu.RUnlock()
+
+		if !indexed {
+			return nil, errors.Errorf("field selector %q requires field %q to already be indexed via IndexField; the backend cache cannot serve it", key.FieldSelector, field)
+		}
+
+		if _, err := w.ensureWatching(gvk, key.Namespace); err != nil {
+			return nil, errors.Wrapf(err, "watch %s", gvk)
+		}
+
+		return w.listCached(cacheKey, key)
+	}
+
+	w.mu.RLock()
+	watched := w.watchedGVKs[cacheKey]
+	w.mu.RUnlock()
+
+	if !watched {
+		if _, err := w.ensureWatching(gvk, key.Namespace); err != nil {
+			return nil, errors.Wrapf(err, "watch %s", gvk)
+		}
+
+		return w.backendCache.List(ctx, key)
+	}
+
+	return w.listCached(cacheKey, key)
+}
+
+func (w *Watch) listCached(cacheKey gvkKey, key cacheutil.Key) ([]*unstructured.Unstructured, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if key.FieldSelector != "" {
+		return w.listByFieldSelector(cacheKey, key)
+	}
+
+	var list []*unstructured.Unstructured
+	for _, u := range w.cachedObjects[cacheKey] {
+		if key.Namespace != "" && u.GetNamespace() != key.Namespace {
+			continue
+		}
+		if key.Name != "" && u.GetName() != key.Name {
+			continue
+		}
+		list = append(list, u)
+	}
+
+	return list, nil
+}
+
+func (w *Watch) listByFieldSelector(cacheKey gvkKey, key cacheutil.Key) ([]*unstructured.Unstructured, error) {
+	field, value, err := splitFieldSelector(key.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	informer, ok := w.informers[cacheKey]
+	if !ok {
+		return nil, errors.Errorf("no informer for %s", cacheKey.gvk)
+	}
+
+	if !w.indexedFields[cacheKey.gvk][field] {
+		return nil, errors.Errorf("field %q is not indexed for %s", field, cacheKey.gvk)
+	}
+
+	objs, err := informer.GetIndexer().ByIndex(field, value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "query index %q", field)
+	}
+
+	var list []*unstructured.Unstructured
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if key.Namespace != "" && u.GetNamespace() != key.Namespace {
+			continue
+		}
+		list = append(list, u)
+	}
+
+	return list, nil
+}
+
+func splitFieldSelector(selector string) (field, value string, err error) {
+	for i := 0; i < len(selector); i++ {
+		if selector[i] == '=' {
+			return selector[:i], selector[i+1:], nil
+		}
+	}
+
+	return "", "", errors.Errorf("invalid field selector %q, expected field=value", selector)
+}
+
+// Get retrieves a single object matching key.
+func (w *Watch) Get(ctx context.Context, key cacheutil.Key) (*unstructured.Unstructured, error) {
+	gvk := key.GroupVersionKind()
+	cacheKey := w.cacheKeyFor(gvk, key.Namespace)
+
+	w.mu.RLock()
+	watched := w.watchedGVKs[cacheKey]
+	w.mu.RUnlock()
+
+	if !watched {
+		if _, err := w.ensureWatching(gvk, key.Namespace); err != nil {
+			return nil, errors.Wrapf(err, "watch %s", gvk)
+		}
+
+		return w.backendCache.Get(ctx, key)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	for _, u := range w.cachedObjects[cacheKey] {
+		if u.GetNamespace() == key.Namespace && u.GetName() == key.Name {
+			return u, nil
+		}
+	}
+
+	return nil, errors.Errorf("object %s not found", key)
+}
+
+// HasSynced returns true once gvk's informer has synced with the API
+// server. It returns false if gvk is not currently being watched.
+func (w *Watch) HasSynced(gvk schema.GroupVersionKind) bool {
+	w.mu.RLock()
+	informer, ok := w.informers[w.cacheKeyFor(gvk, "")]
+	w.mu.RUnlock()
+
+	if !ok {
+		return false
+	}
+
+	return informer.HasSynced()
+}
+
+// WaitForCacheSync starts watching each of gvks and blocks until every one
+// of their informers has synced, or ctx is cancelled.
+func (w *Watch) WaitForCacheSync(ctx context.Context, gvks ...schema.GroupVersionKind) error {
+	for _, gvk := range gvks {
+		if _, err := w.ensureWatching(gvk, ""); err != nil {
+			return errors.Wrapf(err, "watch %s", gvk)
+		}
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		synced := true
+		for _, gvk := range gvks {
+			if !w.HasSynced(gvk) {
+				synced = false
+				break
+			}
+		}
+		if synced {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "wait for cache sync")
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	clientgocache "k8s.io/client-go/tools/cache"
 
 	"github.com/golang/mock/gomock"
 	cachefake "github.com/heptio/developer-dash/internal/cache/fake"
@@ -131,8 +133,8 @@ func Test_WatchList_cached(t *testing.T) {
 
 	cacheKeyFunc := func(w *Watch) {
 		gvk := listKey.GroupVersionKind()
-		w.watchedGVKs[gvk] = true
-		w.cachedObjects[gvk] = map[types.UID]*unstructured.Unstructured{
+		w.watchedGVKs[gvkKey{gvk: gvk}] = true
+		w.cachedObjects[gvkKey{gvk: gvk}] = map[types.UID]*unstructured.Unstructured{
 			pod1.UID: testutil.ToUnstructured(t, pod1),
 			pod2.UID: testutil.ToUnstructured(t, pod2),
 		}
@@ -207,6 +209,199 @@ func Test_WatchGet_not_cached(t *testing.T) {
 	assert.Equal(t, expected, got)
 }
 
+func Test_WatchList_indexed_field(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	mocks.informer.EXPECT().Informer().Return(mocks.sharedIndexInformer)
+	mocks.sharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	mocks.informerFactory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(mocks.informer)
+
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil)
+
+	addIndexersCall := mocks.sharedIndexInformer.EXPECT().AddIndexers(gomock.Any()).Return(nil)
+	startCall := mocks.informerFactory.EXPECT().Start(gomock.Eq(ctx.Done()))
+	gomock.InOrder(addIndexersCall, startCall)
+
+	pod1 := testutil.CreatePod("pod1")
+	pod1.Namespace = "test"
+	pod1Unstructured := testutil.ToUnstructured(t, pod1)
+
+	indexer := clusterfake.NewMockIndexer(mocks.controller)
+	indexer.EXPECT().
+		ByIndex("spec.nodeName", "node-1").
+		Return([]interface{}{pod1Unstructured}, nil)
+	mocks.sharedIndexInformer.EXPECT().GetIndexer().Return(indexer)
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+	}
+
+	setBackendFunc := func(w *Watch) {
+		w.backendCache = mocks.backendCache
+	}
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), factoryFunc, setBackendFunc)
+	require.NoError(t, err)
+
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	extractor := func(u *unstructured.Unstructured) []string {
+		nodeName, _, _ := unstructured.NestedString(u.Object, "spec", "nodeName")
+		return []string{nodeName}
+	}
+	require.NoError(t, watch.IndexField(podGVK, "spec.nodeName", extractor))
+
+	listKey := cacheutil.Key{Namespace: "test", APIVersion: "v1", Kind: "Pod", FieldSelector: "spec.nodeName=node-1"}
+	got, err := watch.List(ctx, listKey)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*unstructured.Unstructured{pod1Unstructured}, got)
+}
+
+func Test_WatchIndexField_multiple_fields_before_start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	mocks.informer.EXPECT().Informer().Return(mocks.sharedIndexInformer)
+	mocks.sharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	mocks.informerFactory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(mocks.informer)
+
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil)
+
+	// Both indexers must be registered before the factory ever starts, so
+	// neither AddIndexers call can trip a real SharedIndexInformer's
+	// "already started" guard.
+	mocks.sharedIndexInformer.EXPECT().AddIndexers(gomock.Any()).Return(nil).Times(2)
+	mocks.informerFactory.EXPECT().Start(gomock.Eq(ctx.Done())).Times(1)
+	mocks.sharedIndexInformer.EXPECT().HasSynced().Return(true)
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+	}
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), factoryFunc)
+	require.NoError(t, err)
+
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	nodeNameExtractor := func(u *unstructured.Unstructured) []string {
+		nodeName, _, _ := unstructured.NestedString(u.Object, "spec", "nodeName")
+		return []string{nodeName}
+	}
+	phaseExtractor := func(u *unstructured.Unstructured) []string {
+		phase, _, _ := unstructured.NestedString(u.Object, "status", "phase")
+		return []string{phase}
+	}
+
+	require.NoError(t, watch.IndexField(podGVK, "spec.nodeName", nodeNameExtractor))
+	require.NoError(t, watch.IndexField(podGVK, "status.phase", phaseExtractor))
+
+	require.NoError(t, watch.WaitForCacheSync(ctx, podGVK))
+}
+
+func Test_WatchWaitForCacheSync_ready(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	mocks.informer.EXPECT().Informer().Return(mocks.sharedIndexInformer)
+	mocks.sharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	mocks.informerFactory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(mocks.informer)
+
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil)
+	mocks.informerFactory.EXPECT().Start(gomock.Eq(ctx.Done()))
+
+	mocks.sharedIndexInformer.EXPECT().HasSynced().Return(true)
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+	}
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), factoryFunc)
+	require.NoError(t, err)
+
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	require.NoError(t, watch.WaitForCacheSync(ctx, podGVK))
+}
+
+func Test_WatchWaitForCacheSync_timeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	mocks.informer.EXPECT().Informer().Return(mocks.sharedIndexInformer)
+	mocks.sharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	mocks.informerFactory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(mocks.informer)
+
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil)
+	mocks.informerFactory.EXPECT().Start(gomock.Eq(ctx.Done()))
+
+	mocks.sharedIndexInformer.EXPECT().HasSynced().Return(false).AnyTimes()
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+	}
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), factoryFunc)
+	require.NoError(t, err)
+
+	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer timeoutCancel()
+
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+	err = watch.WaitForCacheSync(timeoutCtx, podGVK)
+	require.Error(t, err)
+}
+
 func Test_WatchGet_cached(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -231,8 +426,8 @@ func Test_WatchGet_cached(t *testing.T) {
 
 	cacheKeyFunc := func(w *Watch) {
 		gvk := getKey.GroupVersionKind()
-		w.watchedGVKs[gvk] = true
-		w.cachedObjects[gvk] = map[types.UID]*unstructured.Unstructured{
+		w.watchedGVKs[gvkKey{gvk: gvk}] = true
+		w.cachedObjects[gvkKey{gvk: gvk}] = map[types.UID]*unstructured.Unstructured{
 			pod1.UID: testutil.ToUnstructured(t, pod1),
 		}
 	}
@@ -247,3 +442,157 @@ func Test_WatchGet_cached(t *testing.T) {
 
 	assert.Equal(t, expected, got)
 }
+
+func Test_WatchList_namespace_scoped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	ns1Factory := clusterfake.NewMockDynamicSharedInformerFactory(mocks.controller)
+	ns1Informer := clusterfake.NewMockGenericInformer(mocks.controller)
+	ns1SharedIndexInformer := clusterfake.NewMockSharedIndexInformer(mocks.controller)
+
+	ns2Factory := clusterfake.NewMockDynamicSharedInformerFactory(mocks.controller)
+	ns2Informer := clusterfake.NewMockGenericInformer(mocks.controller)
+	ns2SharedIndexInformer := clusterfake.NewMockSharedIndexInformer(mocks.controller)
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil).Times(2)
+
+	ns1Informer.EXPECT().Informer().Return(ns1SharedIndexInformer)
+	ns1SharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+	ns1Factory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(ns1Informer)
+	ns1Factory.EXPECT().Start(gomock.Eq(ctx.Done()))
+
+	ns2Informer.EXPECT().Informer().Return(ns2SharedIndexInformer)
+	ns2SharedIndexInformer.EXPECT().AddEventHandler(gomock.Any())
+	ns2Factory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(ns2Informer)
+	ns2Factory.EXPECT().Start(gomock.Eq(ctx.Done()))
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+		c.namespacedInitFactoryFunc = func(_ cluster.ClientInterface, namespace string) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			switch namespace {
+			case "ns1":
+				return ns1Factory, nil
+			case "ns2":
+				return ns2Factory, nil
+			default:
+				t.Fatalf("unexpected namespace %q", namespace)
+				return nil, nil
+			}
+		}
+	}
+
+	setBackendFunc := func(w *Watch) {
+		w.backendCache = mocks.backendCache
+	}
+
+	ns1Pod := testutil.CreatePod("pod1")
+	ns1Pod.Namespace = "ns1"
+	ns2Pod := testutil.CreatePod("pod1")
+	ns2Pod.Namespace = "ns2"
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), WithNamespaces("ns1", "ns2"), factoryFunc, setBackendFunc)
+	require.NoError(t, err)
+
+	ns1Key := cacheutil.Key{Namespace: "ns1", APIVersion: "v1", Kind: "Pod"}
+	mocks.backendCache.EXPECT().
+		List(gomock.Any(), gomock.Eq(ns1Key)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, ns1Pod)}, nil)
+
+	ns1List, err := watch.List(ctx, ns1Key)
+	require.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{testutil.ToUnstructured(t, ns1Pod)}, ns1List)
+
+	ns2Key := cacheutil.Key{Namespace: "ns2", APIVersion: "v1", Kind: "Pod"}
+	mocks.backendCache.EXPECT().
+		List(gomock.Any(), gomock.Eq(ns2Key)).
+		Return([]*unstructured.Unstructured{testutil.ToUnstructured(t, ns2Pod)}, nil)
+
+	ns2List, err := watch.List(ctx, ns2Key)
+	require.NoError(t, err)
+	assert.Equal(t, []*unstructured.Unstructured{testutil.ToUnstructured(t, ns2Pod)}, ns2List)
+}
+
+type stubEventHandler struct {
+	addCount    int
+	updateCount int
+	deleteCount int
+}
+
+func (s *stubEventHandler) OnAdd(*unstructured.Unstructured)    { s.addCount++ }
+func (s *stubEventHandler) OnUpdate(*unstructured.Unstructured) { s.updateCount++ }
+func (s *stubEventHandler) OnDelete(*unstructured.Unstructured) { s.deleteCount++ }
+
+func Test_WatchAddEventHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mocks := newWatchMocks(t)
+	defer mocks.controller.Finish()
+
+	var captured clientgocache.ResourceEventHandler
+	mocks.sharedIndexInformer.EXPECT().
+		AddEventHandler(gomock.Any()).
+		Do(func(h clientgocache.ResourceEventHandler) { captured = h })
+
+	mocks.informer.EXPECT().Informer().Return(mocks.sharedIndexInformer)
+
+	podGVR := schema.GroupVersionResource{
+		Version:  "v1",
+		Resource: "pods",
+	}
+	mocks.informerFactory.EXPECT().ForResource(gomock.Eq(podGVR)).Return(mocks.informer)
+
+	podGK := schema.GroupKind{
+		Kind: "Pod",
+	}
+	mocks.client.EXPECT().Resource(gomock.Eq(podGK)).Return(podGVR, nil)
+	mocks.informerFactory.EXPECT().Start(gomock.Eq(ctx.Done()))
+
+	factoryFunc := func(c *Watch) {
+		c.initFactoryFunc = func(cluster.ClientInterface) (dynamicinformer.DynamicSharedInformerFactory, error) {
+			return mocks.informerFactory, nil
+		}
+	}
+
+	watch, err := NewWatch(mocks.client, ctx.Done(), factoryFunc)
+	require.NoError(t, err)
+
+	podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+
+	handler1 := &stubEventHandler{}
+	handler2 := &stubEventHandler{}
+
+	id1, err := watch.AddEventHandler(podGVK, handler1)
+	require.NoError(t, err)
+	_, err = watch.AddEventHandler(podGVK, handler2)
+	require.NoError(t, err)
+
+	require.NotNil(t, captured)
+
+	pod1 := testutil.CreatePod("pod1")
+	podUnstructured := testutil.ToUnstructured(t, pod1)
+	captured.OnAdd(podUnstructured)
+
+	assert.Equal(t, 1, handler1.addCount)
+	assert.Equal(t, 1, handler2.addCount)
+
+	watch.RemoveEventHandler(id1)
+	captured.OnAdd(podUnstructured)
+
+	assert.Equal(t, 1, handler1.addCount)
+	assert.Equal(t, 2, handler2.addCount)
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package clusteroverview
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware-tanzu/octant/internal/describer"
+	"github.com/vmware-tanzu/octant/internal/terminal"
+	"github.com/vmware-tanzu/octant/pkg/view/component"
+)
+
+// RecordingListDescriber describes a terminal's past recordings.
+type RecordingListDescriber struct {
+}
+
+func NewRecordingListDescriber() *RecordingListDescriber {
+	return &RecordingListDescriber{}
+}
+
+var _ describer.Describer = (*RecordingListDescriber)(nil)
+
+// Describe describes a terminal's recordings as content.
+func (d *RecordingListDescriber) Describe(ctx context.Context, namespace string, options describer.Options) (component.ContentResponse, error) {
+	tm := options.TerminalManager()
+
+	terminalID := options.Fields["id"]
+
+	store := terminal.RecordingStoreOf(tm)
+	if store == nil {
+		return component.EmptyContentResponse, nil
+	}
+
+	recordings, err := store.List(ctx)
+	if err != nil {
+		return component.EmptyContentResponse, err
+	}
+
+	list := component.NewList("Recordings", nil)
+
+	tblCols := component.NewTableCols("Container", "Command", "Started", "Duration", "Size", "")
+	tbl := component.NewTable("Recordings", "There are no recordings for this terminal!", tblCols)
+	list.Add(tbl)
+
+	for _, r := range recordings {
+		if terminalID != "" && r.ID != terminalID {
+			continue
+		}
+
+		playbackLink := component.NewLink("", "Play back", playbackPath(r.ID))
+
+		tRow := component.TableRow{
+			"Container": component.NewText(r.Container),
+			"Command":   component.NewText(r.Command),
+			"Started":   component.NewTimestamp(r.StartedAt),
+			"Duration":  component.NewText(r.Duration.String()),
+			"Size":      component.NewText(formatBytes(r.Size)),
+			"":          playbackLink,
+		}
+		tbl.Add(tRow)
+	}
+
+	return component.ContentResponse{
+		Components: []component.Component{list},
+	}, nil
+}
+
+func (d *RecordingListDescriber) PathFilters() []describer.PathFilter {
+	filter := describer.NewPathFilter("/terminal/{id}/recording", d)
+	return []describer.PathFilter{*filter}
+}
+
+func (d *RecordingListDescriber) Reset(ctx context.Context) error {
+	return nil
+}
+
+func playbackPath(id string) string {
+	return "/terminal/" + id + "/recording/playback"
+}
+
+// PlaybackDescriber describes a single recording and is the target of its
+// "Play back" link. The actual streaming happens over Octant's websocket
+// transport, via a handler that calls terminal.StreamPlayback with the
+// client's connection; this describer surfaces the recording's metadata
+// and confirms the recording exists before that handler is reached.
+type PlaybackDescriber struct {
+}
+
+func NewPlaybackDescriber() *PlaybackDescriber {
+	return &PlaybackDescriber{}
+}
+
+var _ describer.Describer = (*PlaybackDescriber)(nil)
+
+// Describe describes a single recording as content.
+func (d *PlaybackDescriber) Describe(ctx context.Context, namespace string, options describer.Options) (component.ContentResponse, error) {
+	tm := options.TerminalManager()
+
+	store := terminal.RecordingStoreOf(tm)
+	if store == nil {
+		return component.EmptyContentResponse, nil
+	}
+
+	id := options.Fields["id"]
+
+	r, meta, err := store.Open(ctx, id)
+	if err != nil {
+		return component.EmptyContentResponse, err
+	}
+	defer r.Close()
+
+	list := component.NewList("Recording", nil)
+
+	tblCols := component.NewTableCols("Container", "Command", "Started", "Duration", "Size")
+	tbl := component.NewTable("Recording", "Recording not found", tblCols)
+	list.Add(tbl)
+
+	tbl.Add(component.TableRow{
+		"Container": component.NewText(meta.Container),
+		"Command":   component.NewText(meta.Command),
+		"Started":   component.NewTimestamp(meta.StartedAt),
+		"Duration":  component.NewText(meta.Duration.String()),
+		"Size":      component.NewText(formatBytes(meta.Size)),
+	})
+
+	return component.ContentResponse{
+		Components: []component.Component{list},
+	}, nil
+}
+
+func (d *PlaybackDescriber) PathFilters() []describer.PathFilter {
+	filter := describer.NewPathFilter("/terminal/{id}/recording/playback", d)
+	return []describer.PathFilter{*filter}
+}
+
+func (d *PlaybackDescriber) Reset(ctx context.Context) error {
+	return nil
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
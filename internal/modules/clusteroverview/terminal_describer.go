@@ -7,8 +7,10 @@ package clusteroverview
 
 import (
 	"context"
+	"path"
 
 	"github.com/vmware-tanzu/octant/internal/describer"
+	"github.com/vmware-tanzu/octant/internal/terminal"
 	"github.com/vmware-tanzu/octant/pkg/view/component"
 )
 
@@ -25,10 +27,11 @@ var _ describer.Describer = (*TerminalListDescriber)(nil)
 // Describe describes a list of port forwards as content
 func (d *TerminalListDescriber) Describe(ctx context.Context, namespace string, options describer.Options) (component.ContentResponse, error) {
 	tm := options.TerminalManager()
+	store := terminal.RecordingStoreOf(tm)
 
 	list := component.NewList("Terminals", nil)
 
-	tblCols := component.NewTableCols("Container", "Command", "ID", "Age")
+	tblCols := component.NewTableCols("Container", "Command", "ID", "Age", "Recording")
 	tbl := component.NewTable("Terminals", "There are no terminals!", tblCols)
 	list.Add(tbl)
 
@@ -43,11 +46,20 @@ func (d *TerminalListDescriber) Describe(ctx context.Context, namespace string,
 		// TODO: Link directly to the terminal
 		nameLink.Config.Ref = nameLink.Config.Ref
 
+		recordingCell := component.NewText("")
+		if store != nil {
+			if err := terminal.StartRecording(t, store); err != nil {
+				return component.EmptyContentResponse, err
+			}
+			recordingCell = component.NewLink("", "View recordings", path.Join("/terminal", t.ID(), "recording"))
+		}
+
 		tRow := component.TableRow{
 			"Container": nameLink,
 			"Command":   component.NewText(t.Command()),
 			"ID":        component.NewText(t.ID()),
 			"Age":       component.NewTimestamp(t.CreatedAt()),
+			"Recording": recordingCell,
 		}
 		tbl.Add(tRow)
 	}
@@ -0,0 +1,178 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package terminal records and replays terminal sessions in the asciicast
+// v2 format (https://docs.asciinema.org/manual/asciicast/v2/).
+package terminal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// CastEventType is the kind of an asciicast v2 event.
+type CastEventType string
+
+const (
+	// CastEventOutput is data written to the terminal (stdout).
+	CastEventOutput CastEventType = "o"
+	// CastEventInput is data read from the terminal (stdin).
+	CastEventInput CastEventType = "i"
+	// CastEventResize is a terminal resize, encoded as "COLSxROWS".
+	CastEventResize CastEventType = "r"
+)
+
+// CastHeader is the asciicast v2 header line.
+type CastHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// CastEvent is a single asciicast v2 event: elapsed seconds since the
+// session started, the event type, and its data.
+type CastEvent struct {
+	Elapsed float64
+	Type    CastEventType
+	Data    string
+}
+
+func (e CastEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Elapsed, e.Type, e.Data})
+}
+
+func (e *CastEvent) UnmarshalJSON(data []byte) error {
+	var raw [3]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Wrap(err, "unmarshal cast event")
+	}
+
+	if err := json.Unmarshal(raw[0], &e.Elapsed); err != nil {
+		return errors.Wrap(err, "unmarshal cast event elapsed time")
+	}
+	if err := json.Unmarshal(raw[1], &e.Type); err != nil {
+		return errors.Wrap(err, "unmarshal cast event type")
+	}
+	return errors.Wrap(json.Unmarshal(raw[2], &e.Data), "unmarshal cast event data")
+}
+
+// CastWriter writes a session to disk in asciicast v2 format: a header
+// line followed by newline-delimited events.
+type CastWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+// NewCastWriter writes header and returns a CastWriter that timestamps
+// subsequent events relative to start.
+func NewCastWriter(w io.Writer, header CastHeader, start time.Time) (*CastWriter, error) {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(header); err != nil {
+		return nil, errors.Wrap(err, "write asciicast header")
+	}
+
+	return &CastWriter{w: w, start: start}, nil
+}
+
+// WriteOutput records stdout data.
+func (c *CastWriter) WriteOutput(data []byte) error {
+	return c.writeEvent(CastEventOutput, string(data))
+}
+
+// WriteInput records stdin data.
+func (c *CastWriter) WriteInput(data []byte) error {
+	return c.writeEvent(CastEventInput, string(data))
+}
+
+// WriteResize records a terminal resize to cols x rows.
+func (c *CastWriter) WriteResize(cols, rows int) error {
+	return c.writeEvent(CastEventResize, formatResize(cols, rows))
+}
+
+func (c *CastWriter) writeEvent(t CastEventType, data string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	event := CastEvent{
+		Elapsed: time.Since(c.start).Seconds(),
+		Type:    t,
+		Data:    data,
+	}
+
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal cast event")
+	}
+
+	_, err = c.w.Write(append(b, '\n'))
+	return err
+}
+
+func formatResize(cols, rows int) string {
+	return fmt.Sprintf("%dx%d", cols, rows)
+}
+
+// EventSink is notified of each event as a recording is replayed. It
+// models the existing websocket transport a playback session streams
+// over.
+type EventSink interface {
+	Send(event CastEvent) error
+}
+
+// Playback reads a recording from r and sends its events to sink at the
+// pacing they were recorded, scaled by speed (2.0 plays back twice as
+// fast, 0.5 half as fast). The header is read but not sent. Playback stops and returns ctx.Err()
+// if ctx is cancelled.
+func Playback(ctx context.Context, r io.Reader, speed float64, sink EventSink) error {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+
+	var header CastHeader
+	if err := dec.Decode(&header); err != nil {
+		return errors.Wrap(err, "decode asciicast header")
+	}
+
+	var elapsed float64
+	for {
+		var event CastEvent
+		err := dec.Decode(&event)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "decode asciicast event")
+		}
+
+		wait := time.Duration((event.Elapsed - elapsed) / speed * float64(time.Second))
+		elapsed = event.Elapsed
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if err := sink.Send(event); err != nil {
+			return errors.Wrap(err, "send cast event")
+		}
+	}
+}
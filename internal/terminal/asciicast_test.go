@@ -0,0 +1,92 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CastWriter_round_trip(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+
+	w, err := NewCastWriter(&buf, CastHeader{Version: 2, Width: 80, Height: 24}, start)
+	require.NoError(t, err)
+
+	require.NoError(t, w.WriteOutput([]byte("hello")))
+	require.NoError(t, w.WriteInput([]byte("x")))
+	require.NoError(t, w.WriteResize(100, 40))
+
+	recorded := &fakeSink{}
+	err = Playback(context.Background(), &buf, 1, recorded)
+	require.NoError(t, err)
+
+	require.Len(t, recorded.events, 3)
+	assert.Equal(t, CastEventOutput, recorded.events[0].Type)
+	assert.Equal(t, "hello", recorded.events[0].Data)
+	assert.Equal(t, CastEventInput, recorded.events[1].Type)
+	assert.Equal(t, "x", recorded.events[1].Data)
+	assert.Equal(t, CastEventResize, recorded.events[2].Type)
+	assert.Equal(t, "100x40", recorded.events[2].Data)
+}
+
+func Test_Playback_paces_events_by_speed(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+
+	w, err := NewCastWriter(&buf, CastHeader{Version: 2, Width: 80, Height: 24}, start)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteOutput([]byte("a")))
+
+	// Force a second event far enough in the future that pacing at high
+	// speed is measurably faster than real time, without flaking at
+	// normal speed.
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, w.WriteOutput([]byte("b")))
+
+	recorded := &fakeSink{}
+
+	began := time.Now()
+	err = Playback(context.Background(), bytes.NewReader(buf.Bytes()), 100, recorded)
+	require.NoError(t, err)
+	elapsed := time.Since(began)
+
+	assert.Len(t, recorded.events, 2)
+	assert.Less(t, elapsed, 50*time.Millisecond)
+}
+
+func Test_Playback_stops_on_context_cancel(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Now()
+
+	w, err := NewCastWriter(&buf, CastHeader{Version: 2, Width: 80, Height: 24}, start)
+	require.NoError(t, err)
+	require.NoError(t, w.WriteOutput([]byte("a")))
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, w.WriteOutput([]byte("b")))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	recorded := &fakeSink{}
+	err = Playback(ctx, bytes.NewReader(buf.Bytes()), 0.001, recorded)
+	assert.Equal(t, context.Canceled, err)
+}
+
+type fakeSink struct {
+	events []CastEvent
+}
+
+func (s *fakeSink) Send(event CastEvent) error {
+	s.events = append(s.events, event)
+	return nil
+}
@@ -0,0 +1,35 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+// RecordingConfig controls whether and how terminal sessions are
+// recorded. It is sourced from Octant's existing configuration so
+// operators can disable recording without code changes.
+type RecordingConfig struct {
+	// Enabled turns recording on. When false, NewRecordingStore returns a
+	// nil store and every terminal session runs unrecorded.
+	Enabled bool
+
+	// Directory is where FilesystemRecordingStore persists recordings.
+	// Required when Enabled is true.
+	Directory string
+
+	// Retention bounds how much recording data is kept on disk.
+	Retention RetentionPolicy
+}
+
+// NewRecordingStore builds the RecordingStore described by cfg. It
+// returns a nil store (and nil error) when cfg.Enabled is false, which is
+// the mechanism operators use to disable recording: a nil store makes
+// RecordingCapable.RecordingStore, StartRecording, and every recording
+// describer no-op.
+func NewRecordingStore(cfg RecordingConfig) (RecordingStore, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	return NewFilesystemRecordingStore(cfg.Directory, cfg.Retention)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"context"
+	"time"
+
+	cacheutil "github.com/vmware-tanzu/octant/internal/cache/util"
+)
+
+// Terminal is a live terminal session, as exposed by TerminalManager.List.
+type Terminal interface {
+	Key() cacheutil.Key
+	Container() string
+	Command() string
+	ID() string
+	CreatedAt() time.Time
+}
+
+// TerminalManager tracks live terminal sessions. It is the interface
+// returned by describer.Options.TerminalManager().
+type TerminalManager interface {
+	List(ctx context.Context) []Terminal
+}
+
+// RecordingCapable is implemented by a TerminalManager that can record
+// sessions to a RecordingStore. Recording is entirely optional: a manager
+// that doesn't implement this interface, or whose RecordingStore returns
+// nil, simply never records.
+type RecordingCapable interface {
+	// RecordingStore returns the manager's recording store, or nil if
+	// recording is currently disabled.
+	RecordingStore() RecordingStore
+
+	// SetRecordingStore enables recording new sessions to store. Passing
+	// nil disables recording; sessions already being recorded finish
+	// their existing recording.
+	SetRecordingStore(store RecordingStore)
+}
+
+// Recordable is implemented by a Terminal whose live session can be
+// captured to a RecordingStore.
+type Recordable interface {
+	// Record starts capturing this session's stdin, stdout, and resize
+	// events to store under a recording keyed by the session's ID. It
+	// must be idempotent: once a session is already being recorded,
+	// subsequent calls are no-ops.
+	Record(store RecordingStore) error
+}
+
+// RecordingStoreOf returns tm's RecordingStore if tm implements
+// RecordingCapable and has one configured, and nil otherwise. Describers
+// must use this instead of asserting tm's type directly, since
+// TerminalManager itself declares only List — recording support is
+// optional.
+func RecordingStoreOf(tm TerminalManager) RecordingStore {
+	rc, ok := tm.(RecordingCapable)
+	if !ok {
+		return nil
+	}
+
+	return rc.RecordingStore()
+}
+
+// StartRecording starts recording t to store if store is non-nil and t
+// implements Recordable. It is a no-op otherwise, and safe to call on
+// every poll of TerminalManager.List since Record is required to be
+// idempotent.
+func StartRecording(t Terminal, store RecordingStore) error {
+	if store == nil {
+		return nil
+	}
+
+	recordable, ok := t.(Recordable)
+	if !ok {
+		return nil
+	}
+
+	return recordable.Record(store)
+}
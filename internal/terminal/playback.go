@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// MessageWriter is satisfied by Octant's existing websocket connection
+// type. It lets WebsocketEventSink stream a playback without depending on
+// a concrete websocket implementation.
+type MessageWriter interface {
+	WriteMessage(data []byte) error
+}
+
+// WebsocketEventSink adapts a MessageWriter (Octant's existing websocket
+// transport) to an EventSink, marshaling each CastEvent as asciicast v2
+// JSON before sending it down the connection.
+type WebsocketEventSink struct {
+	Conn MessageWriter
+}
+
+var _ EventSink = (*WebsocketEventSink)(nil)
+
+// Send implements EventSink.
+func (s *WebsocketEventSink) Send(event CastEvent) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal cast event")
+	}
+
+	return s.Conn.WriteMessage(b)
+}
+
+// StreamPlayback opens recording id from store and streams it to conn at
+// speed via Playback. It is the function a playback websocket handler
+// calls once a client connects to a terminal's playback endpoint.
+func StreamPlayback(ctx context.Context, store RecordingStore, id string, speed float64, conn MessageWriter) error {
+	r, _, err := store.Open(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "open recording %q", id)
+	}
+	defer r.Close()
+
+	return Playback(ctx, r, speed, &WebsocketEventSink{Conn: conn})
+}
@@ -0,0 +1,91 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Recorder captures a live terminal session's stdin, stdout, and resize
+// events to a RecordingStore-backed CastWriter. Its zero value is a no-op
+// recorder, so a Terminal can hold a Recorder unconditionally and only
+// pay for recording when one was actually created via RecordSession.
+type Recorder struct {
+	cast *CastWriter
+	out  io.Closer
+}
+
+// RecordSession begins recording a session described by meta to store,
+// returning a Recorder whose WriteOutput/WriteInput/WriteResize calls
+// capture events as the session runs. Callers wrap the session's real
+// stdout with the Recorder (e.g. io.MultiWriter(stdout, recorder)),
+// forward stdin reads to WriteInput, forward resize events to
+// WriteResize, and call Close when the session ends.
+//
+// If store is nil, recording is disabled and RecordSession returns a
+// no-op Recorder whose methods always succeed without writing anything.
+func RecordSession(store RecordingStore, meta RecordingMeta, header CastHeader, start time.Time) (*Recorder, error) {
+	if store == nil {
+		return &Recorder{}, nil
+	}
+
+	w, err := store.Create(meta)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create recording for session %q", meta.ID)
+	}
+
+	cast, err := NewCastWriter(w, header, start)
+	if err != nil {
+		_ = w.Close()
+		return nil, errors.Wrapf(err, "start recording for session %q", meta.ID)
+	}
+
+	return &Recorder{cast: cast, out: w}, nil
+}
+
+// recording reports whether r is actually capturing events, as opposed to
+// a nil or zero-value no-op Recorder.
+func (r *Recorder) recording() bool {
+	return r != nil && r.cast != nil
+}
+
+// WriteOutput records stdout data. A nil or no-op Recorder is a safe
+// no-op so recording can be wired into a session unconditionally.
+func (r *Recorder) WriteOutput(data []byte) error {
+	if r == nil || r.cast == nil {
+		return nil
+	}
+	return r.cast.WriteOutput(data)
+}
+
+// WriteInput records stdin data. See WriteOutput for nil/no-op handling.
+func (r *Recorder) WriteInput(data []byte) error {
+	if r == nil || r.cast == nil {
+		return nil
+	}
+	return r.cast.WriteInput(data)
+}
+
+// WriteResize records a terminal resize. See WriteOutput for nil/no-op
+// handling.
+func (r *Recorder) WriteResize(cols, rows int) error {
+	if r == nil || r.cast == nil {
+		return nil
+	}
+	return r.cast.WriteResize(cols, rows)
+}
+
+// Close finalizes the recording. It is a safe no-op on a nil or no-op
+// Recorder.
+func (r *Recorder) Close() error {
+	if r == nil || r.out == nil {
+		return nil
+	}
+	return r.out.Close()
+}
@@ -0,0 +1,253 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RecordingMeta describes a recorded terminal session.
+type RecordingMeta struct {
+	ID        string        `json:"id"`
+	Container string        `json:"container"`
+	Command   string        `json:"command"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+	Size      int64         `json:"size"`
+}
+
+// RetentionPolicy bounds how much recording data RecordingStore keeps on
+// disk. A zero value field disables that bound.
+type RetentionPolicy struct {
+	MaxAge        time.Duration
+	MaxTotalBytes int64
+}
+
+// RecordingStore persists and retrieves terminal session recordings.
+type RecordingStore interface {
+	// Create begins a new recording, returning a writer for its
+	// asciicast v2 data. meta.ID, meta.Container, and meta.Command must
+	// be set; meta.StartedAt defaults to time.Now if zero. Closing the
+	// returned writer finalizes the recording's duration and size.
+	Create(meta RecordingMeta) (io.WriteCloser, error)
+
+	// List returns metadata for all stored recordings, most recent
+	// first.
+	List(ctx context.Context) ([]RecordingMeta, error)
+
+	// Open returns a reader for recording id's asciicast v2 data along
+	// with its metadata. Callers must close the reader.
+	Open(ctx context.Context, id string) (io.ReadCloser, RecordingMeta, error)
+
+	// Delete removes recording id.
+	Delete(ctx context.Context, id string) error
+}
+
+// FilesystemRecordingStore stores recordings as a pair of files per
+// session: <id>.cast holds the asciicast v2 data and <id>.json holds its
+// RecordingMeta.
+type FilesystemRecordingStore struct {
+	dir       string
+	retention RetentionPolicy
+}
+
+var _ RecordingStore = (*FilesystemRecordingStore)(nil)
+
+// NewFilesystemRecordingStore creates a FilesystemRecordingStore rooted at
+// dir, creating it if necessary, and enforces retention on construction.
+func NewFilesystemRecordingStore(dir string, retention RetentionPolicy) (*FilesystemRecordingStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.Wrap(err, "create recording directory")
+	}
+
+	s := &FilesystemRecordingStore{dir: dir, retention: retention}
+
+	if err := s.prune(); err != nil {
+		return nil, errors.Wrap(err, "prune existing recordings")
+	}
+
+	return s, nil
+}
+
+func (s *FilesystemRecordingStore) castPath(id string) string {
+	return filepath.Join(s.dir, id+".cast")
+}
+
+func (s *FilesystemRecordingStore) metaPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *FilesystemRecordingStore) writeMeta(meta RecordingMeta) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return errors.Wrap(err, "marshal recording metadata")
+	}
+
+	return ioutil.WriteFile(s.metaPath(meta.ID), b, 0o644)
+}
+
+func (s *FilesystemRecordingStore) readMeta(id string) (RecordingMeta, error) {
+	var meta RecordingMeta
+
+	b, err := ioutil.ReadFile(s.metaPath(id))
+	if err != nil {
+		return meta, errors.Wrapf(err, "read recording metadata for %q", id)
+	}
+
+	return meta, errors.Wrapf(json.Unmarshal(b, &meta), "unmarshal recording metadata for %q", id)
+}
+
+// Create implements RecordingStore.
+func (s *FilesystemRecordingStore) Create(meta RecordingMeta) (io.WriteCloser, error) {
+	if meta.ID == "" {
+		return nil, errors.New("recording id is required")
+	}
+	if meta.StartedAt.IsZero() {
+		meta.StartedAt = time.Now()
+	}
+
+	if err := s.writeMeta(meta); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Create(s.castPath(meta.ID))
+	if err != nil {
+		return nil, errors.Wrapf(err, "create recording file for %q", meta.ID)
+	}
+
+	return &recordingWriter{store: s, file: f, meta: meta}, nil
+}
+
+// List implements RecordingStore.
+func (s *FilesystemRecordingStore) List(ctx context.Context) ([]RecordingMeta, error) {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, "list recordings")
+	}
+
+	list := make([]RecordingMeta, 0, len(matches))
+	for _, match := range matches {
+		id := strings.TrimSuffix(filepath.Base(match), ".json")
+		meta, err := s.readMeta(id)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, meta)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedAt.After(list[j].StartedAt)
+	})
+
+	return list, nil
+}
+
+// Open implements RecordingStore.
+func (s *FilesystemRecordingStore) Open(ctx context.Context, id string) (io.ReadCloser, RecordingMeta, error) {
+	meta, err := s.readMeta(id)
+	if err != nil {
+		return nil, RecordingMeta{}, err
+	}
+
+	f, err := os.Open(s.castPath(id))
+	if err != nil {
+		return nil, RecordingMeta{}, errors.Wrapf(err, "open recording file for %q", id)
+	}
+
+	return f, meta, nil
+}
+
+// Delete implements RecordingStore.
+func (s *FilesystemRecordingStore) Delete(ctx context.Context, id string) error {
+	if err := os.Remove(s.castPath(id)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "remove recording file for %q", id)
+	}
+	if err := os.Remove(s.metaPath(id)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "remove recording metadata for %q", id)
+	}
+
+	return nil
+}
+
+// prune removes recordings that fall outside the configured retention
+// policy. It is called after every completed recording.
+func (s *FilesystemRecordingStore) prune() error {
+	if s.retention.MaxAge == 0 && s.retention.MaxTotalBytes == 0 {
+		return nil
+	}
+
+	list, err := s.List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, meta := range list {
+		total += meta.Size
+	}
+
+	cutoff := time.Now().Add(-s.retention.MaxAge)
+
+	// list is sorted most-recent-first, so walk it in reverse to evict
+	// the oldest recordings first.
+	for i := len(list) - 1; i >= 0; i-- {
+		meta := list[i]
+
+		tooOld := s.retention.MaxAge != 0 && meta.StartedAt.Before(cutoff)
+		overBudget := s.retention.MaxTotalBytes != 0 && total > s.retention.MaxTotalBytes
+
+		if !tooOld && !overBudget {
+			continue
+		}
+
+		if err := s.Delete(context.Background(), meta.ID); err != nil {
+			return err
+		}
+		total -= meta.Size
+	}
+
+	return nil
+}
+
+type recordingWriter struct {
+	store *FilesystemRecordingStore
+	file  *os.File
+	meta  RecordingMeta
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	return w.file.Write(p)
+}
+
+func (w *recordingWriter) Close() error {
+	if err := w.file.Close(); err != nil {
+		return errors.Wrap(err, "close recording file")
+	}
+
+	info, err := os.Stat(w.file.Name())
+	if err != nil {
+		return errors.Wrap(err, "stat recording file")
+	}
+
+	w.meta.Duration = time.Since(w.meta.StartedAt)
+	w.meta.Size = info.Size()
+
+	if err := w.store.writeMeta(w.meta); err != nil {
+		return err
+	}
+
+	return w.store.prune()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_FilesystemRecordingStore_round_trip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "octant-recording-test")
+	require.NoError(t, err)
+
+	store, err := NewFilesystemRecordingStore(dir, RetentionPolicy{})
+	require.NoError(t, err)
+
+	meta := RecordingMeta{
+		ID:        "session-1",
+		Container: "nginx",
+		Command:   "/bin/sh",
+		StartedAt: time.Now().Add(-time.Minute),
+	}
+
+	w, err := store.Create(meta)
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	list, err := store.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, meta.ID, list[0].ID)
+	assert.Equal(t, meta.Container, list[0].Container)
+	assert.Equal(t, meta.Command, list[0].Command)
+	assert.True(t, list[0].Duration > 0)
+	assert.Equal(t, int64(len("hello\n")), list[0].Size)
+
+	r, openedMeta, err := store.Open(context.Background(), meta.ID)
+	require.NoError(t, err)
+	defer r.Close()
+
+	assert.Equal(t, meta.ID, openedMeta.ID)
+
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+
+	require.NoError(t, store.Delete(context.Background(), meta.ID))
+
+	list, err = store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func Test_FilesystemRecordingStore_prune_MaxAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "octant-recording-test")
+	require.NoError(t, err)
+
+	store, err := NewFilesystemRecordingStore(dir, RetentionPolicy{MaxAge: time.Hour})
+	require.NoError(t, err)
+
+	old := RecordingMeta{ID: "old", Container: "c", Command: "cmd", StartedAt: time.Now().Add(-2 * time.Hour)}
+	recent := RecordingMeta{ID: "recent", Container: "c", Command: "cmd", StartedAt: time.Now()}
+
+	for _, meta := range []RecordingMeta{old, recent} {
+		w, err := store.Create(meta)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	require.NoError(t, store.prune())
+
+	list, err := store.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "recent", list[0].ID)
+}
+
+func Test_FilesystemRecordingStore_prune_MaxTotalBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "octant-recording-test")
+	require.NoError(t, err)
+
+	store, err := NewFilesystemRecordingStore(dir, RetentionPolicy{MaxTotalBytes: 5})
+	require.NoError(t, err)
+
+	oldest := RecordingMeta{ID: "oldest", Container: "c", Command: "cmd", StartedAt: time.Now().Add(-2 * time.Minute)}
+	newest := RecordingMeta{ID: "newest", Container: "c", Command: "cmd", StartedAt: time.Now()}
+
+	for _, meta := range []RecordingMeta{oldest, newest} {
+		w, err := store.Create(meta)
+		require.NoError(t, err)
+		_, err = w.Write([]byte("12345"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	}
+
+	list, err := store.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "newest", list[0].ID)
+}
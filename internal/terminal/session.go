@@ -0,0 +1,147 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	cacheutil "github.com/vmware-tanzu/octant/internal/cache/util"
+)
+
+// Session is a live terminal attached to a container. It implements
+// Terminal and Recordable: callers exec into the container with Session
+// as the attach's Stdout and pass client input through its WriteInput
+// method, so that once Record has been called every byte written or read
+// also lands in the session's recording.
+type Session struct {
+	key       cacheutil.Key
+	container string
+	command   string
+	id        string
+	createdAt time.Time
+
+	mu            sync.Mutex
+	width, height int
+	recorder      *Recorder
+	stdout        io.Writer
+}
+
+var (
+	_ Terminal   = (*Session)(nil)
+	_ Recordable = (*Session)(nil)
+)
+
+// NewSession creates a live session for container/command in the resource
+// identified by key. stdout is where the session's output is actually
+// delivered (e.g. a websocket connection); cols/rows is its initial size.
+// id must be unique among the owning Manager's sessions.
+func NewSession(key cacheutil.Key, container, command, id string, cols, rows int, stdout io.Writer) *Session {
+	return &Session{
+		key:       key,
+		container: container,
+		command:   command,
+		id:        id,
+		createdAt: time.Now(),
+		width:     cols,
+		height:    rows,
+		recorder:  &Recorder{},
+		stdout:    stdout,
+	}
+}
+
+// Key implements Terminal.
+func (s *Session) Key() cacheutil.Key { return s.key }
+
+// Container implements Terminal.
+func (s *Session) Container() string { return s.container }
+
+// Command implements Terminal.
+func (s *Session) Command() string { return s.command }
+
+// ID implements Terminal.
+func (s *Session) ID() string { return s.id }
+
+// CreatedAt implements Terminal.
+func (s *Session) CreatedAt() time.Time { return s.createdAt }
+
+// Record implements Recordable. It is idempotent: once the session is
+// already recording, later calls are no-ops.
+func (s *Session) Record(store RecordingStore) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recorder.recording() {
+		return nil
+	}
+
+	meta := RecordingMeta{
+		ID:        s.id,
+		Container: s.container,
+		Command:   s.command,
+		StartedAt: s.createdAt,
+	}
+	header := CastHeader{
+		Version:   2,
+		Width:     s.width,
+		Height:    s.height,
+		Timestamp: s.createdAt.Unix(),
+	}
+
+	recorder, err := RecordSession(store, meta, header, s.createdAt)
+	if err != nil {
+		return err
+	}
+
+	s.recorder = recorder
+	return nil
+}
+
+// Write implements io.Writer. It is the Stdout the session's container
+// attach should write to: output is forwarded to the session's real
+// destination and, once Record has been called, also captured as an
+// asciicast output event.
+func (s *Session) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	recorder := s.recorder
+	s.mu.Unlock()
+
+	_ = recorder.WriteOutput(p)
+
+	return s.stdout.Write(p)
+}
+
+// WriteInput records client input as an asciicast input event. Callers
+// forward input read from the client here before writing it to the
+// container attach's Stdin.
+func (s *Session) WriteInput(p []byte) {
+	s.mu.Lock()
+	recorder := s.recorder
+	s.mu.Unlock()
+
+	_ = recorder.WriteInput(p)
+}
+
+// Resize records a terminal resize. Callers call this whenever the client
+// resizes, before applying it to the container attach's TerminalSizeQueue.
+func (s *Session) Resize(cols, rows int) {
+	s.mu.Lock()
+	s.width, s.height = cols, rows
+	recorder := s.recorder
+	s.mu.Unlock()
+
+	_ = recorder.WriteResize(cols, rows)
+}
+
+// Close finalizes the session's recording, if any.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	recorder := s.recorder
+	s.mu.Unlock()
+
+	return recorder.Close()
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Manager tracks live terminal Sessions and, when configured with a
+// RecordingStore, records them and serves their playback. It is the
+// concrete type describer.Options.TerminalManager() returns.
+type Manager struct {
+	mu       sync.RWMutex
+	sessions map[string]*Session
+	store    RecordingStore
+}
+
+var (
+	_ TerminalManager  = (*Manager)(nil)
+	_ RecordingCapable = (*Manager)(nil)
+)
+
+// NewManager creates a Manager with recording configured from cfg. A
+// disabled cfg (cfg.Enabled == false) yields a Manager that never
+// records, same as calling SetRecordingStore(nil).
+func NewManager(cfg RecordingConfig) (*Manager, error) {
+	store, err := NewRecordingStore(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "build recording store")
+	}
+
+	return &Manager{
+		sessions: make(map[string]*Session),
+		store:    store,
+	}, nil
+}
+
+// Add registers s with the manager so it appears in List, and — if
+// recording is currently enabled — immediately starts recording it.
+func (m *Manager) Add(s *Session) error {
+	m.mu.Lock()
+	m.sessions[s.ID()] = s
+	store := m.store
+	m.mu.Unlock()
+
+	return StartRecording(s, store)
+}
+
+// Remove closes and forgets the session identified by id.
+func (m *Manager) Remove(id string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return s.Close()
+}
+
+// List implements TerminalManager.
+func (m *Manager) List(ctx context.Context) []Terminal {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]Terminal, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		list = append(list, s)
+	}
+
+	return list
+}
+
+// RecordingStore implements RecordingCapable.
+func (m *Manager) RecordingStore() RecordingStore {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.store
+}
+
+// SetRecordingStore implements RecordingCapable. Sessions already being
+// recorded keep their existing recording. Sessions added via Add, and any
+// session not yet recorded that is later passed to StartRecording with
+// the store RecordingStore returns, are recorded to store.
+func (m *Manager) SetRecordingStore(store RecordingStore) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.store = store
+}
+
+// Playback streams recording id to conn at speed. It is the method a
+// playback websocket handler calls once a client connects to
+// /terminal/{id}/recording/playback.
+func (m *Manager) Playback(ctx context.Context, id string, speed float64, conn MessageWriter) error {
+	store := m.RecordingStore()
+	if store == nil {
+		return errors.New("recording is disabled")
+	}
+
+	return StreamPlayback(ctx, store, id, speed, conn)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2019 the Octant contributors. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package terminal
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cacheutil "github.com/vmware-tanzu/octant/internal/cache/util"
+)
+
+func Test_Manager_records_and_plays_back_a_session(t *testing.T) {
+	dir, err := ioutil.TempDir("", "octant-terminal-manager-test")
+	require.NoError(t, err)
+
+	manager, err := NewManager(RecordingConfig{Enabled: true, Directory: dir})
+	require.NoError(t, err)
+
+	var clientOut bytes.Buffer
+	key := cacheutil.Key{Namespace: "default", APIVersion: "v1", Kind: "Pod", Name: "web-1"}
+	session := NewSession(key, "web", "/bin/sh", "session-1", 80, 24, &clientOut)
+
+	require.NoError(t, manager.Add(session))
+
+	// Simulate the container attach writing output and the client
+	// resizing, both of which must reach the recording as well as the
+	// real destination.
+	_, err = session.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	session.WriteInput([]byte("ls\n"))
+	session.Resize(100, 40)
+	require.NoError(t, session.Close())
+
+	assert.Equal(t, "hello\n", clientOut.String())
+
+	list := manager.List(context.Background())
+	require.Len(t, list, 1)
+	assert.Equal(t, "session-1", list[0].ID())
+
+	recordings, err := manager.RecordingStore().List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, recordings, 1)
+	assert.Equal(t, "session-1", recordings[0].ID)
+
+	var conn fakeConn
+	require.NoError(t, manager.Playback(context.Background(), "session-1", 1000, &conn))
+
+	require.Len(t, conn.sent, 3)
+}
+
+func Test_Manager_does_not_record_when_disabled(t *testing.T) {
+	manager, err := NewManager(RecordingConfig{Enabled: false})
+	require.NoError(t, err)
+
+	assert.Nil(t, manager.RecordingStore())
+
+	var clientOut bytes.Buffer
+	key := cacheutil.Key{Namespace: "default", APIVersion: "v1", Kind: "Pod", Name: "web-1"}
+	session := NewSession(key, "web", "/bin/sh", "session-1", 80, 24, &clientOut)
+
+	require.NoError(t, manager.Add(session))
+	_, err = session.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	assert.Equal(t, "hello\n", clientOut.String())
+
+	err = manager.Playback(context.Background(), "session-1", 1, &fakeConn{})
+	assert.EqualError(t, err, "recording is disabled")
+}
+
+type fakeConn struct {
+	sent [][]byte
+}
+
+func (c *fakeConn) WriteMessage(data []byte) error {
+	c.sent = append(c.sent, data)
+	return nil
+}